@@ -17,13 +17,7 @@
 package main
 
 import (
-	"crypto/rand"
-	"crypto/rsa"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -34,39 +28,47 @@ import (
 const renewDaysLimit = 45 // Number of days.
 
 // getCert expiration time.
-func getCertExpTime(certsDir string) (time.Time, error) {
-	certBytes, err := loadCert(certsDir)
+func getCertExpTime(store CertStore) (time.Time, error) {
+	certBytes, err := store.Load()
 	if err != nil {
 		return time.Time{}, err
 	}
 	return acme.GetPEMCertExpiration(certBytes)
 }
 
-// isValidDomain validates if input string is a valid domain name.
+// isValidDomain validates if input string is a valid domain name. host
+// is expected to already be in ASCII-Compatible Encoding (see toACE),
+// since A-labels are what this validates against RFC 1035.
 func isValidDomain(host string) bool {
-	// See RFC 1035, RFC 3696.
 	host = strings.TrimSpace(host)
 	if len(host) == 0 || len(host) > 255 {
 		return false
 	}
-	// host cannot start or end with "-"
-	if host[len(host)-1:] == "-" || host[:1] == "-" {
-		return false
+	for _, label := range strings.Split(host, ".") {
+		if !isValidLabel(label) {
+			return false
+		}
 	}
-	// host cannot start or end with "_"
-	if host[len(host)-1:] == "_" || host[:1] == "_" {
+	return true
+}
+
+// isValidLabel validates a single DNS label per RFC 1035: 1 to 63
+// characters, letters/digits/hyphens only (the LDH rule), and no
+// leading or trailing hyphen.
+func isValidLabel(label string) bool {
+	if len(label) == 0 || len(label) > 63 {
 		return false
 	}
-	// host cannot start or end with a "."
-	if host[len(host)-1:] == "." || host[:1] == "." {
+	if label[0] == '-' || label[len(label)-1] == '-' {
 		return false
 	}
-	// All non alphanumeric characters are invalid.
-	if strings.ContainsAny(host, "`~!@#$%^&*()+={}[]|\\\"';:><?/") {
-		return false
+	for _, r := range label {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+		default:
+			return false
+		}
 	}
-	// No need to regexp match, since the list is non-exhaustive.
-	// We let it valid and fail later.
 	return true
 }
 
@@ -77,13 +79,43 @@ func isSubDomain(domain string) bool {
 	return len(domainParts) > 2
 }
 
-// generate certificates.
-func genCerts(email, domain string, subDomains []string) (acme.CertificateResource, error) {
+// generate certificates. The ACME account's private key is returned in
+// PEM form alongside the certificate, so callers can persist it with
+// CertStore.SaveAccountKey and later deactivate that same account (see
+// deactivateAccount).
+func genCerts(email, domain string, subDomains []string, challenge ChallengeConfig, keyType KeyType) (acme.CertificateResource, []byte, error) {
+	// Let's Encrypt only accepts A-labels, so convert domain and
+	// subDomains to ASCII-Compatible Encoding up front and reject
+	// anything that doesn't come out as a valid domain.
+	domain, err := toACE(domain)
+	if err != nil {
+		return acme.CertificateResource{}, nil, err
+	}
+	if !isValidDomain(domain) {
+		return acme.CertificateResource{}, nil, fmt.Errorf("Invalid domain name %q.", domain)
+	}
+	// Build a new slice rather than rewriting subDomains in place, so
+	// callers don't see their slice silently replaced with ACE labels.
+	aceSubDomains := make([]string, len(subDomains))
+	for i, subDomain := range subDomains {
+		subDomain, err = toACE(subDomain)
+		if err != nil {
+			return acme.CertificateResource{}, nil, err
+		}
+		if !isValidLabel(subDomain) {
+			return acme.CertificateResource{}, nil, fmt.Errorf("Invalid subdomain name %q.", subDomain)
+		}
+		aceSubDomains[i] = subDomain
+	}
+
 	// Create a user. New accounts need an email and private key to start with.
-	const rsaKeySize = 2048
-	privateKey, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	privateKey, err := generatePrivateKey(keyType)
+	if err != nil {
+		return acme.CertificateResource{}, nil, err
+	}
+	accountKeyPEM, err := marshalPrivateKeyPEM(privateKey)
 	if err != nil {
-		return acme.CertificateResource{}, err
+		return acme.CertificateResource{}, nil, err
 	}
 
 	// Initialize user.
@@ -93,17 +125,19 @@ func genCerts(email, domain string, subDomains []string) (acme.CertificateResour
 	}
 
 	// A client facilitates communication with the CA server.
-	client, err := acme.NewClient(acmeServer, &user, acme.RSA2048)
+	client, err := acme.NewClient(acmeServer, &user, acme.KeyType(keyType))
 	if err != nil {
-		return acme.CertificateResource{}, err
+		return acme.CertificateResource{}, nil, err
 	}
 
-	client.ExcludeChallenges([]acme.Challenge{acme.DNS01})
+	if err = setChallengeProvider(client, challenge); err != nil {
+		return acme.CertificateResource{}, nil, err
+	}
 
 	// New users will need to register; be sure to save it
 	reg, err := client.Register()
 	if err != nil {
-		return acme.CertificateResource{}, err
+		return acme.CertificateResource{}, nil, err
 	}
 	user.Registration = reg
 
@@ -111,12 +145,17 @@ func genCerts(email, domain string, subDomains []string) (acme.CertificateResour
 	// Agreement. The user will need to agree to it.
 	err = client.AgreeToTOS()
 	if err != nil {
-		return acme.CertificateResource{}, err
+		return acme.CertificateResource{}, nil, err
 	}
 
 	domains := []string{domain}
-	if !isSubDomain(domain) {
-		for _, subDomain := range subDomains {
+	if challenge.DNS.Wildcard {
+		// Wildcard SANs can only be validated with DNS-01, and are
+		// not subdomains of domain so they're added independent of
+		// the subDomains list.
+		domains = append(domains, wildcardDomain(domain))
+	} else if !isSubDomain(domain) {
+		for _, subDomain := range aceSubDomains {
 			domains = append(domains, subDomain+"."+domain)
 		}
 	}
@@ -133,29 +172,49 @@ func genCerts(email, domain string, subDomains []string) (acme.CertificateResour
 			failedDomainsErrors = append(failedDomainsErrors, failedDomainErr)
 		}
 		failure := fmt.Errorf("Failed to obtain certificates for Domains: %s, with following errors %s respectively.", failedDomains, failedDomainsErrors)
-		return acme.CertificateResource{}, failure
+		return acme.CertificateResource{}, nil, failure
 	}
-	return newCertificates, nil
+	return newCertificates, accountKeyPEM, nil
 }
 
-// Renew certificates.
-func renewCerts(certsDir, email string) (acme.CertificateResource, error) {
-	certBytes, err := loadCert(certsDir)
+// Renew certificates. If reuseKey is set, the existing private.key on
+// disk is kept across the renewal instead of generating a new one -
+// useful for HPKP-style pinning or to simply speed up rotation. keyType
+// is only used to generate a fresh key; pass KeyType("") to fall back to
+// the key type recorded in certs.json from the original genCerts call.
+// The KeyType actually used, and the renewing ACME account's private key
+// in PEM form, are returned alongside the certificate, so callers
+// persist the resolved values (see CertStore.Save/SaveAccountKey)
+// instead of the possibly-empty keyType they passed in.
+func renewCerts(store CertStore, email string, challenge ChallengeConfig, keyType KeyType, reuseKey bool) (acme.CertificateResource, KeyType, []byte, error) {
+	certBytes, err := store.Load()
 	if err != nil {
-		return acme.CertificateResource{}, err
+		return acme.CertificateResource{}, keyType, nil, err
 	}
 
 	expTime, err := acme.GetPEMCertExpiration(certBytes)
 	expTimeDays := int(expTime.Sub(time.Now()).Hours() / 24.0)
 	if expTimeDays > renewDaysLimit {
-		return acme.CertificateResource{}, fmt.Errorf("Keys have not expired yet, please renew in %d days.", expTimeDays)
+		return acme.CertificateResource{}, keyType, nil, fmt.Errorf("Keys have not expired yet, please renew in %d days.", expTimeDays)
+	}
+
+	certMeta, err := store.LoadMeta()
+	if err != nil {
+		return acme.CertificateResource{}, keyType, nil, err
+	}
+
+	if keyType == KeyType("") {
+		keyType = certMeta.KeyType
 	}
 
 	// Create a user. New accounts need an email and private key to start with.
-	const rsaKeySize = 2048
-	privateKey, err := rsa.GenerateKey(rand.Reader, rsaKeySize)
+	privateKey, err := generatePrivateKey(keyType)
+	if err != nil {
+		return acme.CertificateResource{}, keyType, nil, err
+	}
+	accountKeyPEM, err := marshalPrivateKeyPEM(privateKey)
 	if err != nil {
-		return acme.CertificateResource{}, err
+		return acme.CertificateResource{}, keyType, nil, err
 	}
 
 	// Initialize user.
@@ -167,81 +226,30 @@ func renewCerts(certsDir, email string) (acme.CertificateResource, error) {
 	// A client facilitates communication with the CA server. This CA
 	// URL is configured for a local dev instance of Boulder running
 	// in Docker in a VM.
-	client, err := acme.NewClient(acmeServer, &user, acme.RSA2048)
+	client, err := acme.NewClient(acmeServer, &user, acme.KeyType(keyType))
 	if err != nil {
-		return acme.CertificateResource{}, err
+		return acme.CertificateResource{}, keyType, nil, err
 	}
 
-	client.ExcludeChallenges([]acme.Challenge{acme.DNS01})
-
-	certMeta, err := loadCertMeta(certsDir)
-	if err != nil {
-		return acme.CertificateResource{}, err
+	if err = setChallengeProvider(client, challenge); err != nil {
+		return acme.CertificateResource{}, keyType, nil, err
 	}
 
 	// Save current cert bytes.
 	certMeta.Certificate = certBytes
 
-	isBundle := true // Bundle all domains into one.
-	newCertificates, err := client.RenewCertificate(certMeta, isBundle)
-	if err != nil {
-		return acme.CertificateResource{}, err
-	}
-	return newCertificates, nil
-}
-
-// load certificate meta resource.
-func loadCertMeta(certsDir string) (acme.CertificateResource, error) {
-	metaBytes, err := ioutil.ReadFile(filepath.Join(certsDir, "certs.json"))
-	if err != nil {
-		return acme.CertificateResource{}, err
-	}
-	var certRes acme.CertificateResource
-	err = json.Unmarshal(metaBytes, &certRes)
-	if err != nil {
-		return acme.CertificateResource{}, err
-	}
-	return certRes, nil
-}
-
-// load certs.
-func loadCert(certsDir string) ([]byte, error) {
-	return ioutil.ReadFile(filepath.Join(certsDir, "public.crt"))
-}
-
-// saveCerts saves the certificates to disk. This includes the
-// certificate file itself, the private key, and the json metadata file.
-func saveCerts(certsDir string, cert acme.CertificateResource) error {
-	// Save cert file.
-	err := ioutil.WriteFile(filepath.Join(certsDir, "public.crt"), cert.Certificate, 0600)
-	if err != nil {
-		return err
-	}
-
-	// Save private key.
-	err = ioutil.WriteFile(filepath.Join(certsDir, "private.key"), cert.PrivateKey, 0600)
-	if err != nil {
-		return err
-	}
-
-	// Save cert metadata.
-	jsonBytes, err := json.MarshalIndent(&cert, "", "\t")
-	if err != nil {
-		return err
+	if reuseKey {
+		keyBytes, err := store.LoadKey()
+		if err != nil {
+			return acme.CertificateResource{}, keyType, nil, err
+		}
+		certMeta.PrivateKey = keyBytes
 	}
 
-	err = ioutil.WriteFile(filepath.Join(certsDir, "certs.json"), jsonBytes, 0600)
+	isBundle := true // Bundle all domains into one.
+	newCertificates, err := client.RenewCertificate(certMeta.CertificateResource, isBundle)
 	if err != nil {
-		return err
+		return acme.CertificateResource{}, keyType, nil, err
 	}
-
-	// Return success.
-	return nil
-}
-
-// Verify if certs are available in a certs dir.
-func isCertAvailable(certsDir string) bool {
-	_, crtErr := os.Stat(filepath.Join(certsDir, "public.crt"))
-	_, keyErr := os.Stat(filepath.Join(certsDir, "private.key"))
-	return crtErr == nil && keyErr == nil
+	return newCertificates, keyType, accountKeyPEM, nil
 }
@@ -0,0 +1,77 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsValidLabel(t *testing.T) {
+	cases := []struct {
+		label string
+		want  bool
+	}{
+		{"example", true},
+		{"xn--bcher-kva", true},
+		{"", false},
+		{strings.Repeat("a", 63), true},
+		{strings.Repeat("a", 64), false},
+		{"-example", false},
+		{"example-", false},
+		{"exa_mple", false},
+	}
+	for _, c := range cases {
+		if got := isValidLabel(c.label); got != c.want {
+			t.Errorf("isValidLabel(%q) = %v, want %v", c.label, got, c.want)
+		}
+	}
+}
+
+func TestIsValidDomain(t *testing.T) {
+	cases := []struct {
+		domain string
+		want   bool
+	}{
+		{"example.com", true},
+		{"www.example.com", true},
+		{"", false},
+		{"example.-com", false},
+		{strings.Repeat("a", 256), false},
+	}
+	for _, c := range cases {
+		if got := isValidDomain(c.domain); got != c.want {
+			t.Errorf("isValidDomain(%q) = %v, want %v", c.domain, got, c.want)
+		}
+	}
+}
+
+func TestIsSubDomain(t *testing.T) {
+	cases := []struct {
+		domain string
+		want   bool
+	}{
+		{"example.com", false},
+		{"www.example.com", true},
+		{"a.b.c.example.com", true},
+	}
+	for _, c := range cases {
+		if got := isSubDomain(c.domain); got != c.want {
+			t.Errorf("isSubDomain(%q) = %v, want %v", c.domain, got, c.want)
+		}
+	}
+}
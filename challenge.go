@@ -0,0 +1,85 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/xenolf/lego/acme"
+	"github.com/xenolf/lego/providers/tlsalpn01"
+)
+
+// TLSALPNConfig configures the TLS-ALPN-01 challenge, useful when port
+// 80 is unreachable but 443 is - common behind container/Kubernetes
+// ingresses.
+type TLSALPNConfig struct {
+	// Enabled turns on TLS-ALPN-01.
+	Enabled bool
+
+	// ListenAddr is the address:port the TLS-ALPN-01 challenge server
+	// listens on, e.g. ":443" or "0.0.0.0:443". Defaults to ":443".
+	ListenAddr string
+}
+
+// ChallengeConfig selects and configures which ACME challenge
+// genCerts/renewCerts use to prove domain ownership. The zero value
+// uses HTTP-01, matching Concert's original behavior. When more than
+// one challenge is configured, DNS-01 takes priority over TLS-ALPN-01,
+// which takes priority over HTTP-01.
+type ChallengeConfig struct {
+	// DNS configures DNS-01. Leave its Provider field empty to disable
+	// DNS-01.
+	DNS DNSProviderConfig
+
+	// TLSALPN configures TLS-ALPN-01.
+	TLSALPN TLSALPNConfig
+}
+
+// setChallengeProvider configures client to use the challenge selected
+// by cfg, excluding the others.
+func setChallengeProvider(client *acme.Client, cfg ChallengeConfig) error {
+	if cfg.DNS.Wildcard && cfg.DNS.Provider == "" {
+		// Wildcard SANs can only be validated with DNS-01.
+		return fmt.Errorf("wildcard certificates require a DNS-01 provider to be configured")
+	}
+
+	if cfg.DNS.Provider != "" {
+		dnsProvider, err := newDNSProvider(cfg.DNS.Provider)
+		if err != nil {
+			return err
+		}
+		client.ExcludeChallenges([]acme.Challenge{acme.HTTP01, acme.TLSALPN01})
+		return client.SetChallengeProvider(acme.DNS01, dnsProvider)
+	}
+
+	if cfg.TLSALPN.Enabled {
+		listenAddr := cfg.TLSALPN.ListenAddr
+		if listenAddr == "" {
+			listenAddr = ":443"
+		}
+		host, port, err := net.SplitHostPort(listenAddr)
+		if err != nil {
+			return err
+		}
+		client.ExcludeChallenges([]acme.Challenge{acme.HTTP01, acme.DNS01})
+		return client.SetChallengeProvider(acme.TLSALPN01, tlsalpn01.NewProviderServer(host, port))
+	}
+
+	client.ExcludeChallenges([]acme.Challenge{acme.DNS01, acme.TLSALPN01})
+	return nil
+}
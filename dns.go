@@ -0,0 +1,68 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/xenolf/lego/acme"
+	"github.com/xenolf/lego/providers/dns/cloudflare"
+	"github.com/xenolf/lego/providers/dns/digitalocean"
+	"github.com/xenolf/lego/providers/dns/gcloud"
+	"github.com/xenolf/lego/providers/dns/route53"
+)
+
+// DNSProviderConfig selects and configures the DNS-01 challenge for a
+// genCerts/renewCerts call. The zero value leaves DNS-01 disabled, in
+// which case HTTP-01 is used as before.
+type DNSProviderConfig struct {
+	// Provider is the name of the DNS provider to use to fulfil the
+	// DNS-01 challenge, e.g. "route53", "cloudflare", "digitalocean"
+	// or "gcloud". Leave empty to disable DNS-01.
+	Provider string
+
+	// Wildcard requests a wildcard SAN (*.domain) instead of appending
+	// subDomains to domain. Only valid when Provider is set, since
+	// Let's Encrypt only allows wildcard names to be validated with
+	// DNS-01.
+	Wildcard bool
+}
+
+// newDNSProvider returns the lego challenge provider for the named DNS
+// backend. Each provider reads its own credentials from the environment
+// (for example AWS_ACCESS_KEY_ID/AWS_SECRET_ACCESS_KEY for route53, or
+// CLOUDFLARE_EMAIL/CLOUDFLARE_API_KEY for cloudflare).
+func newDNSProvider(name string) (acme.ChallengeProvider, error) {
+	switch name {
+	case "route53":
+		return route53.NewDNSProvider()
+	case "cloudflare":
+		return cloudflare.NewDNSProvider()
+	case "digitalocean":
+		return digitalocean.NewDNSProvider()
+	case "gcloud":
+		return gcloud.NewDNSProvider()
+	default:
+		return nil, fmt.Errorf("Unknown DNS provider %q.", name)
+	}
+}
+
+// wildcardDomain returns the wildcard SAN for domain, e.g. "*.example.com"
+// for "example.com".
+func wildcardDomain(domain string) string {
+	return "*." + domain
+}
@@ -0,0 +1,33 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "golang.org/x/net/idna"
+
+// aceProfile is idna.Lookup plus DNS length checks, so empty or
+// over-long labels are rejected too.
+var aceProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.VerifyDNSLength(true))
+
+// toACE converts domain to its ASCII-Compatible Encoding (punycode)
+// form, e.g. "bücher.de" to "xn--bcher-kva.de". Plain ASCII domains
+// pass through unchanged; domains that break the IDNA lookup rules
+// (disallowed code points, bidi or hyphen rules, empty or over-long
+// labels) are rejected. Labels that mix scripts, such as Latin with
+// Cyrillic, are not detected and are converted like any other.
+func toACE(domain string) (string, error) {
+	return aceProfile.ToASCII(domain)
+}
@@ -0,0 +1,48 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "testing"
+
+func TestToACE(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "example.com", want: "example.com"},
+		{in: "bücher.de", want: "xn--bcher-kva.de"},
+		{in: "xn--bcher-kva.de", want: "xn--bcher-kva.de"},
+		{in: "apple..com", wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := toACE(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("toACE(%q): expected error, got %q", c.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("toACE(%q): unexpected error: %v", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("toACE(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/xenolf/lego/acme"
+)
+
+// KeyType identifies the algorithm and size used to generate an account
+// or certificate private key. It mirrors the key types lego's acme
+// package already knows how to negotiate with the CA.
+type KeyType acme.KeyType
+
+// Supported key types.
+const (
+	KeyRSA2048 = KeyType(acme.RSA2048)
+	KeyRSA3072 = KeyType(acme.RSA3072)
+	KeyRSA4096 = KeyType(acme.RSA4096)
+	KeyEC256   = KeyType(acme.EC256)
+	KeyEC384   = KeyType(acme.EC384)
+)
+
+// defaultKeyType is used whenever a caller does not specify one, keeping
+// prior behavior of always issuing 2048 bit RSA keys.
+const defaultKeyType = KeyRSA2048
+
+// parseKeyType maps a human readable key type, as it would appear in a
+// config file or flag, to a KeyType. Accepted values are "rsa2048",
+// "rsa3072", "rsa4096", "ec256" and "ec384".
+func parseKeyType(s string) (KeyType, error) {
+	switch s {
+	case "", "rsa2048":
+		return KeyRSA2048, nil
+	case "rsa3072":
+		return KeyRSA3072, nil
+	case "rsa4096":
+		return KeyRSA4096, nil
+	case "ec256":
+		return KeyEC256, nil
+	case "ec384":
+		return KeyEC384, nil
+	default:
+		return "", fmt.Errorf("Unknown key type %q.", s)
+	}
+}
+
+// generatePrivateKey creates a new private key of the given type.
+func generatePrivateKey(keyType KeyType) (crypto.PrivateKey, error) {
+	switch keyType {
+	case KeyEC256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case KeyEC384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case KeyRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case KeyRSA3072:
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case KeyRSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	default:
+		return nil, fmt.Errorf("Unknown key type %q.", keyType)
+	}
+}
+
+// marshalPrivateKeyPEM encodes key, as returned by generatePrivateKey,
+// back into the PEM form parsePrivateKeyPEM understands.
+func marshalPrivateKeyPEM(key crypto.PrivateKey) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)}), nil
+	case *ecdsa.PrivateKey:
+		keyBytes, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), nil
+	default:
+		return nil, fmt.Errorf("Unsupported private key type %T.", key)
+	}
+}
+
+// parsePrivateKeyPEM parses a PEM encoded private key, such as the bytes
+// returned by CertStore.LoadKey. It understands both the PKCS1 RSA and
+// SEC1 EC encodings lego writes out. Used by certificate-key-
+// authenticated revocation (see revokeCert) and by deactivateAccount.
+func parsePrivateKeyPEM(keyBytes []byte) (crypto.PrivateKey, error) {
+	keyBlock, _ := pem.Decode(keyBytes)
+	if keyBlock == nil {
+		return nil, fmt.Errorf("Unable to decode PEM block from private key.")
+	}
+
+	switch keyBlock.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(keyBlock.Bytes)
+	default:
+		return nil, fmt.Errorf("Unsupported private key type %q.", keyBlock.Type)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "testing"
+
+func TestParseKeyType(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    KeyType
+		wantErr bool
+	}{
+		{"", KeyRSA2048, false},
+		{"rsa2048", KeyRSA2048, false},
+		{"rsa3072", KeyRSA3072, false},
+		{"rsa4096", KeyRSA4096, false},
+		{"ec256", KeyEC256, false},
+		{"ec384", KeyEC384, false},
+		{"rsa512", "", true},
+	}
+	for _, c := range cases {
+		got, err := parseKeyType(c.in)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("parseKeyType(%q): expected error", c.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseKeyType(%q): unexpected error: %v", c.in, err)
+			continue
+		}
+		if got != c.want {
+			t.Errorf("parseKeyType(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestGeneratePrivateKeyUnknownType(t *testing.T) {
+	if _, err := generatePrivateKey(KeyType("bogus")); err == nil {
+		t.Error("generatePrivateKey(bogus): expected error")
+	}
+}
+
+func TestMarshalParsePrivateKeyRoundTrip(t *testing.T) {
+	key, err := generatePrivateKey(KeyEC256)
+	if err != nil {
+		t.Fatalf("generatePrivateKey: %v", err)
+	}
+	keyPEM, err := marshalPrivateKeyPEM(key)
+	if err != nil {
+		t.Fatalf("marshalPrivateKeyPEM: %v", err)
+	}
+	if _, err = parsePrivateKeyPEM(keyPEM); err != nil {
+		t.Fatalf("parsePrivateKeyPEM: %v", err)
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "gen":
+		err = cmdGen(os.Args[2:])
+	case "renew":
+		err = cmdRenew(os.Args[2:])
+	case "renew-daemon":
+		err = cmdRenewDaemon(os.Args[2:])
+	case "revoke":
+		err = cmdRevoke(os.Args[2:])
+	case "deactivate":
+		err = cmdDeactivate(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "concert %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: concert <gen|renew|renew-daemon|revoke|deactivate> [flags]")
+}
+
+// cmdGen obtains a new certificate and stores it under -certs-dir.
+func cmdGen(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	certsDir := fs.String("certs-dir", "", "directory to store the certificate in")
+	email := fs.String("email", "", "account email")
+	domain := fs.String("domain", "", "domain to obtain a certificate for")
+	subDomains := fs.String("sub-domains", "", "comma separated list of subdomains")
+	keyTypeFlag := fs.String("key-type", "", "key type: rsa2048, rsa3072, rsa4096, ec256 or ec384")
+	fs.Parse(args)
+
+	if *certsDir == "" || *email == "" || *domain == "" {
+		return fmt.Errorf("-certs-dir, -email and -domain are required")
+	}
+	keyType, err := parseKeyType(*keyTypeFlag)
+	if err != nil {
+		return err
+	}
+
+	cert, accountKeyPEM, err := genCerts(*email, *domain, splitSubDomains(*subDomains), ChallengeConfig{}, keyType)
+	if err != nil {
+		return err
+	}
+
+	store := NewFSCertStore(*certsDir)
+	if err = store.Save(cert, keyType); err != nil {
+		return err
+	}
+	return store.SaveAccountKey(accountKeyPEM)
+}
+
+// cmdRenew renews the certificate stored under -certs-dir once.
+func cmdRenew(args []string) error {
+	fs := flag.NewFlagSet("renew", flag.ExitOnError)
+	certsDir := fs.String("certs-dir", "", "directory holding the certificate to renew")
+	email := fs.String("email", "", "account email")
+	reuseKey := fs.Bool("reuse-key", false, "keep the existing private key across the renewal")
+	keyTypeFlag := fs.String("key-type", "", "key type: rsa2048, rsa3072, rsa4096, ec256 or ec384")
+	fs.Parse(args)
+
+	if *certsDir == "" || *email == "" {
+		return fmt.Errorf("-certs-dir and -email are required")
+	}
+	keyType, err := parseKeyType(*keyTypeFlag)
+	if err != nil {
+		return err
+	}
+
+	store := NewFSCertStore(*certsDir)
+	cert, resolvedKeyType, accountKeyPEM, err := renewCerts(store, *email, ChallengeConfig{}, keyType, *reuseKey)
+	if err != nil {
+		return err
+	}
+	if err = store.Save(cert, resolvedKeyType); err != nil {
+		return err
+	}
+	return store.SaveAccountKey(accountKeyPEM)
+}
+
+// cmdRenewDaemon starts a RenewalManager for the certificate under
+// -certs-dir and logs its events until interrupted.
+func cmdRenewDaemon(args []string) error {
+	fs := flag.NewFlagSet("renew-daemon", flag.ExitOnError)
+	certsDir := fs.String("certs-dir", "", "directory holding the certificate to keep renewed")
+	email := fs.String("email", "", "account email")
+	reuseKey := fs.Bool("reuse-key", false, "keep the existing private key across renewals")
+	fs.Parse(args)
+
+	if *certsDir == "" || *email == "" {
+		return fmt.Errorf("-certs-dir and -email are required")
+	}
+
+	manager := NewRenewalManager(RenewalConfig{
+		Store:    NewFSCertStore(*certsDir),
+		Email:    *email,
+		ReuseKey: *reuseKey,
+	})
+	manager.Start()
+
+	for event := range manager.Events {
+		if event.Err != nil {
+			fmt.Fprintf(os.Stderr, "renew-daemon: %v: %v\n", event.Type, event.Err)
+			continue
+		}
+		fmt.Printf("renew-daemon: %v at %s\n", event.Type, event.Time.Format("2006-01-02T15:04:05Z"))
+	}
+	return nil
+}
+
+// cmdRevoke revokes the certificate stored under -certs-dir.
+func cmdRevoke(args []string) error {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	certsDir := fs.String("certs-dir", "", "directory holding the certificate to revoke")
+	email := fs.String("email", "", "account email")
+	fs.Parse(args)
+
+	if *certsDir == "" || *email == "" {
+		return fmt.Errorf("-certs-dir and -email are required")
+	}
+	return revokeCert(*certsDir, *email)
+}
+
+// cmdDeactivate deactivates the ACME account that issued the
+// certificate stored under -certs-dir.
+func cmdDeactivate(args []string) error {
+	fs := flag.NewFlagSet("deactivate", flag.ExitOnError)
+	certsDir := fs.String("certs-dir", "", "directory holding the certificate whose account to deactivate")
+	email := fs.String("email", "", "account email")
+	fs.Parse(args)
+
+	if *certsDir == "" || *email == "" {
+		return fmt.Errorf("-certs-dir and -email are required")
+	}
+	return deactivateAccount(*certsDir, *email)
+}
+
+// splitSubDomains splits a comma separated subdomain list, skipping
+// empty entries so "" yields no subdomains instead of one empty one.
+func splitSubDomains(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var subDomains []string
+	for _, sub := range strings.Split(s, ",") {
+		sub = strings.TrimSpace(sub)
+		if sub != "" {
+			subDomains = append(subDomains, sub)
+		}
+	}
+	return subDomains
+}
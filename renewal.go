@@ -0,0 +1,210 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RenewalEventType identifies what happened on a RenewalManager tick.
+type RenewalEventType int
+
+// Renewal lifecycle events.
+const (
+	// Renewed - the certificate was successfully renewed.
+	Renewed RenewalEventType = iota
+	// Failed - the certificate or its metadata could not be loaded, or
+	// a renewal was attempted and exhausted its retries.
+	Failed
+	// Skipped - the certificate still has enough validity left, or a
+	// renewal was attempted too recently after a restart.
+	Skipped
+)
+
+// RenewalEvent is sent on a RenewalManager's Events channel after every
+// check, so the host process can log or alert on it.
+type RenewalEvent struct {
+	Type RenewalEventType
+	Err  error
+	Time time.Time
+}
+
+// RenewalConfig configures a RenewalManager.
+type RenewalConfig struct {
+	Store     CertStore
+	Email     string
+	Challenge ChallengeConfig
+	KeyType   KeyType
+	ReuseKey  bool
+
+	// CheckInterval is how often the manager wakes up to check the
+	// certificate's remaining validity. Defaults to 1 hour.
+	CheckInterval time.Duration
+	// RenewDaysThreshold triggers a renewal once the certificate has
+	// fewer than this many days left, matching lego's "renew --days"
+	// flag. Defaults to 30.
+	RenewDaysThreshold int
+	// MaxJitter randomizes the start of each check by up to this
+	// duration, to avoid a thundering herd of simultaneous renewals
+	// against the ACME CA. Defaults to one tenth of CheckInterval.
+	MaxJitter time.Duration
+	// MaxRetries bounds the retry/backoff attempts for a single failed
+	// renewal before giving up until the next CheckInterval tick.
+	// Defaults to 3.
+	MaxRetries int
+}
+
+// RenewalManager renews a certificate in the background on a timer,
+// instead of requiring an operator to call renewCerts by hand.
+type RenewalManager struct {
+	cfg    RenewalConfig
+	Events chan RenewalEvent
+	stop   chan struct{}
+}
+
+// NewRenewalManager creates a RenewalManager from cfg, applying defaults
+// for any zero-valued fields. Call Start to begin the background loop.
+func NewRenewalManager(cfg RenewalConfig) *RenewalManager {
+	if cfg.CheckInterval == 0 {
+		cfg.CheckInterval = time.Hour
+	}
+	if cfg.RenewDaysThreshold == 0 {
+		cfg.RenewDaysThreshold = 30
+	}
+	if cfg.MaxJitter == 0 {
+		cfg.MaxJitter = cfg.CheckInterval / 10
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = 3
+	}
+	return &RenewalManager{
+		cfg:    cfg,
+		Events: make(chan RenewalEvent, 1),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start launches the renewal loop in a new goroutine.
+func (m *RenewalManager) Start() {
+	go m.run()
+}
+
+// Stop terminates the renewal loop. It does not close Events.
+func (m *RenewalManager) Stop() {
+	close(m.stop)
+}
+
+func (m *RenewalManager) run() {
+	for {
+		if !m.sleep(m.jitter()) {
+			return
+		}
+		m.tick()
+		if !m.sleep(m.cfg.CheckInterval) {
+			return
+		}
+	}
+}
+
+// jitter returns a random duration in [0, MaxJitter).
+func (m *RenewalManager) jitter() time.Duration {
+	if m.cfg.MaxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(m.cfg.MaxJitter)))
+}
+
+// sleep waits for d or until Stop is called, reporting whether the
+// manager should keep running.
+func (m *RenewalManager) sleep(d time.Duration) bool {
+	select {
+	case <-m.stop:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func (m *RenewalManager) tick() {
+	expTime, err := getCertExpTime(m.cfg.Store)
+	if err != nil {
+		m.emit(Failed, err)
+		return
+	}
+
+	daysLeft := int(time.Until(expTime).Hours() / 24.0)
+	if daysLeft >= m.cfg.RenewDaysThreshold {
+		m.emit(Skipped, nil)
+		return
+	}
+
+	meta, err := m.cfg.Store.LoadMeta()
+	if err != nil {
+		m.emit(Failed, err)
+		return
+	}
+	if !meta.LastAttempt.IsZero() && time.Since(meta.LastAttempt) < m.cfg.CheckInterval {
+		// A restart landed us here shortly after a previous attempt;
+		// wait for the next regular tick instead of hammering the CA.
+		m.emit(Skipped, nil)
+		return
+	}
+
+	var renewErr error
+	for attempt := 0; attempt <= m.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if !m.sleep(time.Duration(attempt) * time.Minute) {
+				return
+			}
+		}
+		if renewErr = m.renewOnce(); renewErr == nil {
+			m.emit(Renewed, nil)
+			return
+		}
+	}
+	m.emit(Failed, renewErr)
+}
+
+func (m *RenewalManager) renewOnce() error {
+	meta, err := m.cfg.Store.LoadMeta()
+	if err != nil {
+		return err
+	}
+	meta.LastAttempt = time.Now()
+	if err = m.cfg.Store.SaveMeta(meta); err != nil {
+		return err
+	}
+
+	cert, resolvedKeyType, accountKeyPEM, err := renewCerts(m.cfg.Store, m.cfg.Email, m.cfg.Challenge, m.cfg.KeyType, m.cfg.ReuseKey)
+	if err != nil {
+		return err
+	}
+	if err = m.cfg.Store.Save(cert, resolvedKeyType); err != nil {
+		return err
+	}
+	return m.cfg.Store.SaveAccountKey(accountKeyPEM)
+}
+
+func (m *RenewalManager) emit(t RenewalEventType, err error) {
+	select {
+	case m.Events <- RenewalEvent{Type: t, Err: err, Time: time.Now()}:
+	default:
+		// Drop the event rather than block the renewal loop if no one
+		// is listening.
+	}
+}
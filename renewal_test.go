@@ -0,0 +1,83 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRenewalManagerDefaults(t *testing.T) {
+	m := NewRenewalManager(RenewalConfig{})
+	if m.cfg.CheckInterval != time.Hour {
+		t.Errorf("CheckInterval = %v, want %v", m.cfg.CheckInterval, time.Hour)
+	}
+	if m.cfg.RenewDaysThreshold != 30 {
+		t.Errorf("RenewDaysThreshold = %d, want 30", m.cfg.RenewDaysThreshold)
+	}
+	if m.cfg.MaxJitter != m.cfg.CheckInterval/10 {
+		t.Errorf("MaxJitter = %v, want %v", m.cfg.MaxJitter, m.cfg.CheckInterval/10)
+	}
+	if m.cfg.MaxRetries != 3 {
+		t.Errorf("MaxRetries = %d, want 3", m.cfg.MaxRetries)
+	}
+}
+
+func TestNewRenewalManagerRespectsOverrides(t *testing.T) {
+	m := NewRenewalManager(RenewalConfig{
+		CheckInterval:      time.Minute,
+		RenewDaysThreshold: 10,
+		MaxJitter:          time.Second,
+		MaxRetries:         1,
+	})
+	if m.cfg.CheckInterval != time.Minute {
+		t.Errorf("CheckInterval = %v, want %v", m.cfg.CheckInterval, time.Minute)
+	}
+	if m.cfg.RenewDaysThreshold != 10 {
+		t.Errorf("RenewDaysThreshold = %d, want 10", m.cfg.RenewDaysThreshold)
+	}
+	if m.cfg.MaxJitter != time.Second {
+		t.Errorf("MaxJitter = %v, want %v", m.cfg.MaxJitter, time.Second)
+	}
+	if m.cfg.MaxRetries != 1 {
+		t.Errorf("MaxRetries = %d, want 1", m.cfg.MaxRetries)
+	}
+}
+
+func TestJitterBounded(t *testing.T) {
+	m := NewRenewalManager(RenewalConfig{MaxJitter: 10 * time.Millisecond})
+	for i := 0; i < 100; i++ {
+		if j := m.jitter(); j < 0 || j >= 10*time.Millisecond {
+			t.Fatalf("jitter() = %v, want in [0, 10ms)", j)
+		}
+	}
+}
+
+func TestJitterZeroWhenDisabled(t *testing.T) {
+	m := NewRenewalManager(RenewalConfig{MaxJitter: -1})
+	if j := m.jitter(); j != 0 {
+		t.Errorf("jitter() = %v, want 0", j)
+	}
+}
+
+func TestSleepReturnsFalseAfterStop(t *testing.T) {
+	m := NewRenewalManager(RenewalConfig{})
+	m.Stop()
+	if m.sleep(time.Hour) {
+		t.Error("sleep() after Stop() = true, want false")
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xenolf/lego/acme"
+)
+
+// revokeCert revokes the certificate stored in certsDir and archives
+// the revoked public.crt, private.key and certs.json as timestamped
+// files under a revoked/ subdirectory, so operators keep an audit trail
+// of what was revoked and when.
+//
+// A real ACME CA only authorizes revocation from the account that
+// issued the certificate, or from proof of possession of the
+// certificate's own private key (RFC 8555 section 7.6). This
+// authenticates the revocation with the certificate's own key, so it
+// does not depend on the saved account.key (see deactivateAccount).
+func revokeCert(certsDir, email string) error {
+	store := NewFSCertStore(certsDir)
+
+	certBytes, err := store.Load()
+	if err != nil {
+		return err
+	}
+
+	keyBytes, err := store.LoadKey()
+	if err != nil {
+		return err
+	}
+	certKey, err := parsePrivateKeyPEM(keyBytes)
+	if err != nil {
+		return err
+	}
+
+	user := conUser{Email: email, key: certKey}
+	client, err := acme.NewClient(acmeServer, &user, acme.RSA2048)
+	if err != nil {
+		return err
+	}
+
+	if err = client.RevokeCertificate(certBytes); err != nil {
+		return err
+	}
+
+	return archiveRevoked(certsDir)
+}
+
+// archiveRevoked moves the current certificate material into a
+// timestamped file under certsDir/revoked, so the revoked certificate
+// stops being served as the live one in certsDir. Files are first
+// copied into revokedDir and only removed from certsDir once every
+// copy has succeeded, so a failure during the copy phase leaves
+// certsDir untouched; the archive copy in revokedDir is the safety net
+// if the removal phase itself is later interrupted.
+func archiveRevoked(certsDir string) error {
+	revokedDir := filepath.Join(certsDir, "revoked")
+	if err := os.MkdirAll(revokedDir, 0700); err != nil {
+		return err
+	}
+
+	names := []string{"public.crt", "private.key", "certs.json"}
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(certsDir, name))
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(revokedDir, fmt.Sprintf("%s.%s", stamp, name))
+		if err = ioutil.WriteFile(dst, data, 0600); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(certsDir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deactivateAccount deactivates the ACME account that issued the
+// certificate in certsDir, so a stale or compromised account can no
+// longer be used to issue or renew certificates.
+//
+// ACME account identity is tied to the account's key pair, not its
+// email, so this loads the account key genCerts/renewCerts persisted
+// via CertStore.SaveAccountKey and registers with it again: the CA
+// recognizes the key and returns the existing account rather than
+// creating a new one, which is what lets this reach the real account
+// to delete.
+func deactivateAccount(certsDir, email string) error {
+	store := NewFSCertStore(certsDir)
+
+	keyBytes, err := store.LoadAccountKey()
+	if err != nil {
+		return err
+	}
+	accountKey, err := parsePrivateKeyPEM(keyBytes)
+	if err != nil {
+		return err
+	}
+
+	user := conUser{Email: email, key: accountKey}
+	client, err := acme.NewClient(acmeServer, &user, acme.RSA2048)
+	if err != nil {
+		return err
+	}
+
+	reg, err := client.Register()
+	if err != nil {
+		return err
+	}
+	user.Registration = reg
+
+	return client.DeleteRegistration()
+}
@@ -0,0 +1,132 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/xenolf/lego/acme"
+)
+
+// certMetadata extends lego's CertificateResource with the Concert-
+// specific fields that need to survive a restart, and is what a
+// CertStore actually persists to certs.json (or its equivalent).
+type certMetadata struct {
+	acme.CertificateResource
+	KeyType KeyType
+
+	// LastAttempt records when a RenewalManager last started a renewal
+	// attempt, so a RenewalManager restarted shortly afterwards does not
+	// immediately hammer the ACME CA again. Save writes it as zero, so
+	// it is cleared once a renewal succeeds.
+	LastAttempt time.Time
+}
+
+// CertStore abstracts where certificate material is persisted: local
+// disk, memcached, or an S3/Minio bucket.
+type CertStore interface {
+	// Save persists the certificate, private key and metadata as a unit.
+	Save(cert acme.CertificateResource, keyType KeyType) error
+	// SaveMeta persists metadata only, without touching the cert or key
+	// on disk. Used by RenewalManager to record renewal attempts.
+	SaveMeta(meta certMetadata) error
+	// Load returns the current certificate in PEM form.
+	Load() ([]byte, error)
+	// LoadMeta returns the current certificate metadata.
+	LoadMeta() (certMetadata, error)
+	// LoadKey returns the current private key in PEM form, for
+	// --reuse-key renewals.
+	LoadKey() ([]byte, error)
+	// Exists reports whether a certificate and key are both present.
+	Exists() bool
+	// SaveAccountKey persists the ACME account private key in PEM form.
+	SaveAccountKey(keyPEM []byte) error
+	// LoadAccountKey returns the current account private key in PEM form.
+	LoadAccountKey() ([]byte, error)
+}
+
+// fsCertStore is the default CertStore, keeping certs.json, public.crt
+// and private.key on local disk under Dir. This is the storage backend
+// Concert has always used.
+type fsCertStore struct {
+	Dir string
+}
+
+// NewFSCertStore returns a CertStore backed by the local filesystem,
+// storing certificates under dir as before.
+func NewFSCertStore(dir string) CertStore {
+	return &fsCertStore{Dir: dir}
+}
+
+func (s *fsCertStore) Save(cert acme.CertificateResource, keyType KeyType) error {
+	if err := ioutil.WriteFile(filepath.Join(s.Dir, "public.crt"), cert.Certificate, 0600); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(s.Dir, "private.key"), cert.PrivateKey, 0600); err != nil {
+		return err
+	}
+	return s.SaveMeta(certMetadata{CertificateResource: cert, KeyType: keyType})
+}
+
+func (s *fsCertStore) SaveMeta(meta certMetadata) error {
+	jsonBytes, err := json.MarshalIndent(&meta, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.Dir, "certs.json"), jsonBytes, 0600)
+}
+
+func (s *fsCertStore) Load() ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(s.Dir, "public.crt"))
+}
+
+func (s *fsCertStore) LoadKey() ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(s.Dir, "private.key"))
+}
+
+func (s *fsCertStore) LoadMeta() (certMetadata, error) {
+	metaBytes, err := ioutil.ReadFile(filepath.Join(s.Dir, "certs.json"))
+	if err != nil {
+		return certMetadata{}, err
+	}
+	var certMeta certMetadata
+	if err = json.Unmarshal(metaBytes, &certMeta); err != nil {
+		return certMetadata{}, err
+	}
+	if certMeta.KeyType == KeyType("") {
+		certMeta.KeyType = defaultKeyType
+	}
+	return certMeta, nil
+}
+
+func (s *fsCertStore) Exists() bool {
+	_, crtErr := os.Stat(filepath.Join(s.Dir, "public.crt"))
+	_, keyErr := os.Stat(filepath.Join(s.Dir, "private.key"))
+	return crtErr == nil && keyErr == nil
+}
+
+func (s *fsCertStore) SaveAccountKey(keyPEM []byte) error {
+	return ioutil.WriteFile(filepath.Join(s.Dir, "account.key"), keyPEM, 0600)
+}
+
+func (s *fsCertStore) LoadAccountKey() ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(s.Dir, "account.key"))
+}
@@ -0,0 +1,111 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/xenolf/lego/acme"
+)
+
+// Keys under which certificate material is stored in memcached.
+const (
+	memcachedCertKey       = "concert/public.crt"
+	memcachedKeyKey        = "concert/private.key"
+	memcachedMetaKey       = "concert/certs.json"
+	memcachedAccountKeyKey = "concert/account.key"
+)
+
+// memcachedCertStore stores certificate material in memcached, letting
+// several Concert instances behind the same pool share one certificate.
+type memcachedCertStore struct {
+	client *memcache.Client
+}
+
+// NewMemcachedCertStore returns a CertStore backed by the given
+// memcached hosts (e.g. "10.0.0.1:11211").
+func NewMemcachedCertStore(hosts []string) CertStore {
+	return &memcachedCertStore{client: memcache.New(hosts...)}
+}
+
+func (s *memcachedCertStore) Save(cert acme.CertificateResource, keyType KeyType) error {
+	if err := s.client.Set(&memcache.Item{Key: memcachedCertKey, Value: cert.Certificate}); err != nil {
+		return err
+	}
+	if err := s.client.Set(&memcache.Item{Key: memcachedKeyKey, Value: cert.PrivateKey}); err != nil {
+		return err
+	}
+	return s.SaveMeta(certMetadata{CertificateResource: cert, KeyType: keyType})
+}
+
+func (s *memcachedCertStore) SaveMeta(meta certMetadata) error {
+	metaBytes, err := json.Marshal(&meta)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(&memcache.Item{Key: memcachedMetaKey, Value: metaBytes})
+}
+
+func (s *memcachedCertStore) Load() ([]byte, error) {
+	item, err := s.client.Get(memcachedCertKey)
+	if err != nil {
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+func (s *memcachedCertStore) LoadKey() ([]byte, error) {
+	item, err := s.client.Get(memcachedKeyKey)
+	if err != nil {
+		return nil, err
+	}
+	return item.Value, nil
+}
+
+func (s *memcachedCertStore) LoadMeta() (certMetadata, error) {
+	item, err := s.client.Get(memcachedMetaKey)
+	if err != nil {
+		return certMetadata{}, err
+	}
+	var certMeta certMetadata
+	if err = json.Unmarshal(item.Value, &certMeta); err != nil {
+		return certMetadata{}, err
+	}
+	if certMeta.KeyType == KeyType("") {
+		certMeta.KeyType = defaultKeyType
+	}
+	return certMeta, nil
+}
+
+func (s *memcachedCertStore) Exists() bool {
+	_, crtErr := s.client.Get(memcachedCertKey)
+	_, keyErr := s.client.Get(memcachedKeyKey)
+	return crtErr == nil && keyErr == nil
+}
+
+func (s *memcachedCertStore) SaveAccountKey(keyPEM []byte) error {
+	return s.client.Set(&memcache.Item{Key: memcachedAccountKeyKey, Value: keyPEM})
+}
+
+func (s *memcachedCertStore) LoadAccountKey() ([]byte, error) {
+	item, err := s.client.Get(memcachedAccountKeyKey)
+	if err != nil {
+		return nil, err
+	}
+	return item.Value, nil
+}
@@ -0,0 +1,125 @@
+/*
+ * Concert (C) 2016 Minio, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+
+	minio "github.com/minio/minio-go"
+	"github.com/xenolf/lego/acme"
+)
+
+// Object names under which certificate material is stored in the bucket.
+const (
+	s3CertObject       = "public.crt"
+	s3KeyObject        = "private.key"
+	s3MetaObject       = "certs.json"
+	s3AccountKeyObject = "account.key"
+)
+
+// s3CertStore stores certificate material as objects in an S3 or Minio
+// bucket, letting every node behind the same endpoint and bucket share
+// one certificate.
+type s3CertStore struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewS3CertStore returns a CertStore backed by the given S3/Minio
+// bucket. objectPrefix is prepended to every object name, so several
+// certificates can share one bucket.
+func NewS3CertStore(client *minio.Client, bucket, objectPrefix string) CertStore {
+	return &s3CertStore{client: client, bucket: bucket, prefix: objectPrefix}
+}
+
+func (s *s3CertStore) objectName(name string) string {
+	return s.prefix + name
+}
+
+func (s *s3CertStore) putObject(name string, data []byte) error {
+	_, err := s.client.PutObject(s.bucket, s.objectName(name), bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+	})
+	return err
+}
+
+func (s *s3CertStore) getObject(name string) ([]byte, error) {
+	obj, err := s.client.GetObject(s.bucket, s.objectName(name), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+	return ioutil.ReadAll(obj)
+}
+
+func (s *s3CertStore) Save(cert acme.CertificateResource, keyType KeyType) error {
+	if err := s.putObject(s3CertObject, cert.Certificate); err != nil {
+		return err
+	}
+	if err := s.putObject(s3KeyObject, cert.PrivateKey); err != nil {
+		return err
+	}
+	return s.SaveMeta(certMetadata{CertificateResource: cert, KeyType: keyType})
+}
+
+func (s *s3CertStore) SaveMeta(meta certMetadata) error {
+	metaBytes, err := json.MarshalIndent(&meta, "", "\t")
+	if err != nil {
+		return err
+	}
+	return s.putObject(s3MetaObject, metaBytes)
+}
+
+func (s *s3CertStore) Load() ([]byte, error) {
+	return s.getObject(s3CertObject)
+}
+
+func (s *s3CertStore) LoadKey() ([]byte, error) {
+	return s.getObject(s3KeyObject)
+}
+
+func (s *s3CertStore) LoadMeta() (certMetadata, error) {
+	metaBytes, err := s.getObject(s3MetaObject)
+	if err != nil {
+		return certMetadata{}, err
+	}
+	var certMeta certMetadata
+	if err = json.Unmarshal(metaBytes, &certMeta); err != nil {
+		return certMetadata{}, err
+	}
+	if certMeta.KeyType == KeyType("") {
+		certMeta.KeyType = defaultKeyType
+	}
+	return certMeta, nil
+}
+
+func (s *s3CertStore) Exists() bool {
+	_, crtErr := s.client.StatObject(s.bucket, s.objectName(s3CertObject), minio.StatObjectOptions{})
+	_, keyErr := s.client.StatObject(s.bucket, s.objectName(s3KeyObject), minio.StatObjectOptions{})
+	return crtErr == nil && keyErr == nil
+}
+
+func (s *s3CertStore) SaveAccountKey(keyPEM []byte) error {
+	return s.putObject(s3AccountKeyObject, keyPEM)
+}
+
+func (s *s3CertStore) LoadAccountKey() ([]byte, error) {
+	return s.getObject(s3AccountKeyObject)
+}